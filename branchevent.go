@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/bndr/gojenkins"
+)
+
+// Classify the branch event that triggered a multibranch build: push, pr or indexing.
+// Returns an empty string for builds without a branch cause (non-multibranch jobs).
+func branchEvent(causes []map[string]interface{}) string {
+	for _, cause := range causes {
+		class, _ := cause["_class"].(string)
+		description, _ := cause["shortDescription"].(string)
+		switch class {
+		case "jenkins.branch.BranchIndexingCause":
+			return "indexing"
+		case "jenkins.branch.BranchEventCause":
+			description = strings.ToLower(description)
+			if strings.Contains(description, "pull request") || strings.Contains(description, "merge request") {
+				return "pr"
+			}
+			return "push"
+		}
+	}
+	return ""
+}
+
+// Collect the branch event cause of a multibranch build
+func collectBranchEvent(commonArgs []string, build *gojenkins.Build) {
+	for _, action := range build.GetActions() {
+		if event := branchEvent(action.Causes); event != "" {
+			jenkinsCompletedBuildBranchEvent.WithLabelValues(append(commonArgs, event)...).Set(1)
+			return
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestBranchEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		causes   []map[string]interface{}
+		expected string
+	}{
+		{"push", []map[string]interface{}{
+			{"_class": "jenkins.branch.BranchEventCause", "shortDescription": "Push event to branch master"},
+		}, "push"},
+		{"pr", []map[string]interface{}{
+			{"_class": "jenkins.branch.BranchEventCause", "shortDescription": "Pull request #42 updated"},
+		}, "pr"},
+		{"indexing", []map[string]interface{}{
+			{"_class": "jenkins.branch.BranchIndexingCause", "shortDescription": "Branch indexing"},
+		}, "indexing"},
+		{"non-multibranch", []map[string]interface{}{
+			{"_class": "hudson.model.Cause$UserIdCause", "shortDescription": "Started by user admin"},
+			{"_class": "hudson.triggers.SCMTrigger$SCMTriggerCause", "shortDescription": "Started by an SCM change"},
+		}, ""},
+	}
+	for _, test := range tests {
+		if got := branchEvent(test.causes); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reports how long ago each metric group was last refreshed from Jenkins.
+// The age is computed on every scrape so it keeps growing between refreshes.
+type cacheAgeCollector struct {
+	mu            sync.Mutex
+	refreshed     map[string]time.Time
+	refreshing    bool
+	lastRefresh   time.Time
+	desc          *prometheus.Desc
+	fromCacheDesc *prometheus.Desc
+}
+
+var cacheAge = newCacheAgeCollector()
+
+// Time allowed on top of the update interval for the next refresh to connect to
+// Jenkins, so the data is not reported as expired at the start of every cycle
+const refreshGracePeriod = time.Minute
+
+func newCacheAgeCollector() *cacheAgeCollector {
+	return &cacheAgeCollector{
+		refreshed: make(map[string]time.Time),
+		desc: prometheus.NewDesc(
+			"jenkins_exporter_cache_age_seconds",
+			"Seconds since the metric group was last refreshed from Jenkins",
+			[]string{"group"}, nil),
+		fromCacheDesc: prometheus.NewDesc(
+			"jenkins_exporter_served_from_cache",
+			"1 unless a refresh is in progress or the poller is stalled past the update interval",
+			nil, nil),
+	}
+}
+
+// Record the start of a full refresh from Jenkins
+func (c *cacheAgeCollector) startRefresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = true
+}
+
+// Record the end of a full refresh from Jenkins
+func (c *cacheAgeCollector) finishRefresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = false
+	c.lastRefresh = time.Now()
+}
+
+// Whether a scrape at this time is served from cached data
+func (c *cacheAgeCollector) servedFromCache(ttl time.Duration) bool {
+	return !c.refreshing && !c.lastRefresh.IsZero() && time.Since(c.lastRefresh) <= ttl
+}
+
+// Record that a metric group has just been refreshed
+func (c *cacheAgeCollector) markRefreshed(group string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshed[group] = time.Now()
+}
+
+// Describe implements prometheus.Collector
+func (c *cacheAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+	ch <- c.fromCacheDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *cacheAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for group, refreshed := range c.refreshed {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, time.Since(refreshed).Seconds(), group)
+	}
+
+	fromCache := 0.0
+	if c.servedFromCache(time.Duration(config.Jenkins.UpdateInterval)*time.Second + refreshGracePeriod) {
+		fromCache = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.fromCacheDesc, prometheus.GaugeValue, fromCache)
+}
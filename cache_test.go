@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Value of a metric collected from the cache collector, filtered by group when set
+func cacheMetric(t *testing.T, c *cacheAgeCollector, desc *prometheus.Desc, group string) (float64, bool) {
+	t.Helper()
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	value, found := 0.0, false
+	for metric := range ch {
+		if metric.Desc() != desc {
+			continue
+		}
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if group != "" && (len(m.Label) != 1 || m.Label[0].GetValue() != group) {
+			continue
+		}
+		value, found = m.Gauge.GetValue(), true
+	}
+	return value, found
+}
+
+func TestCacheAge(t *testing.T) {
+	c := newCacheAgeCollector()
+	if _, found := cacheMetric(t, c, c.desc, "jobs"); found {
+		t.Fatal("expected no age before the first refresh")
+	}
+
+	c.markRefreshed("jobs")
+	first, _ := cacheMetric(t, c, c.desc, "jobs")
+	time.Sleep(20 * time.Millisecond)
+	second, _ := cacheMetric(t, c, c.desc, "jobs")
+	if second <= first {
+		t.Errorf("expected the age to grow between refreshes, got %v then %v", first, second)
+	}
+
+	c.markRefreshed("jobs")
+	third, _ := cacheMetric(t, c, c.desc, "jobs")
+	if third >= second {
+		t.Errorf("expected the age to reset on refresh, got %v then %v", second, third)
+	}
+}
+
+func TestServedFromCache(t *testing.T) {
+	c := newCacheAgeCollector()
+	if c.servedFromCache(time.Minute) {
+		t.Error("expected no cache before the first refresh")
+	}
+
+	c.startRefresh()
+	if c.servedFromCache(time.Minute) {
+		t.Error("expected 0 while a refresh is in progress")
+	}
+	c.finishRefresh()
+	if !c.servedFromCache(time.Minute) {
+		t.Error("expected 1 within the TTL")
+	}
+
+	c.lastRefresh = time.Now().Add(-2 * time.Minute)
+	if c.servedFromCache(time.Minute) {
+		t.Error("expected 0 after the TTL expired")
+	}
+}
+
+func TestServedFromCacheMetric(t *testing.T) {
+	defer func(saved uint64) { config.Jenkins.UpdateInterval = saved }(config.Jenkins.UpdateInterval)
+	config.Jenkins.UpdateInterval = 60
+	c := newCacheAgeCollector()
+	c.startRefresh()
+	c.finishRefresh()
+
+	if got, _ := cacheMetric(t, c, c.fromCacheDesc, ""); got != 1 {
+		t.Errorf("expected 1 within the update interval, got %v", got)
+	}
+	// The next refresh is still connecting to Jenkins
+	c.lastRefresh = time.Now().Add(-90 * time.Second)
+	if got, _ := cacheMetric(t, c, c.fromCacheDesc, ""); got != 1 {
+		t.Errorf("expected 1 within the grace period, got %v", got)
+	}
+	c.lastRefresh = time.Now().Add(-3 * time.Minute)
+	if got, _ := cacheMetric(t, c, c.fromCacheDesc, ""); got != 0 {
+		t.Errorf("expected 0 once the poller is stalled, got %v", got)
+	}
+	c.startRefresh()
+	if got, _ := cacheMetric(t, c, c.fromCacheDesc, ""); got != 0 {
+		t.Errorf("expected 0 during a fresh fetch, got %v", got)
+	}
+}
@@ -10,11 +10,24 @@ type Config struct {
 }
 
 type jenkins struct {
-	URL            string
-	User           string
-	Password       string
-	Jobs           []string
-	UpdateInterval uint64
+	URL                      string
+	User                     string
+	Password                 string
+	Jobs                     []string
+	UpdateInterval           uint64
+	HistoryDepth             int
+	MaxTestCasesPerBuild     int
+	CollectPromotions        bool
+	DescriptionMarker        string
+	DescriptionLabels        []string
+	DescriptionDefaultExport bool
+	UseRSSFallback           bool
+	CollectUpstreamDepth     bool
+	MaxUpstreamDepth         int
+	HashLabels               []string
+	HashSalt                 string
+	CollectUpdateCenter      bool
+	CollectCredentials       bool
 }
 
 // Load configuration
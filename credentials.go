@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// System credentials store, credentials are grouped by domain
+type credentialsStore struct {
+	Domains map[string]struct {
+		Credentials []map[string]interface{} `json:"credentials"`
+	} `json:"domains"`
+}
+
+// Attributes a credential may use to expose its expiry
+var credentialExpiryKeys = []string{"expiry", "expiryDate", "expirationDate", "expiresAt", "notAfter"}
+
+// Expiry of a credential, either epoch (seconds or milliseconds) or RFC3339.
+// The second value is false when the credential does not expose an expiry.
+func credentialExpiry(credential map[string]interface{}) (time.Time, bool) {
+	for _, key := range credentialExpiryKeys {
+		switch value := credential[key].(type) {
+		case float64:
+			if value > 1e11 {
+				return time.Unix(0, int64(value)*int64(time.Millisecond)), true
+			}
+			return time.Unix(int64(value), 0), true
+		case string:
+			if expiry, err := time.Parse(time.RFC3339, value); err == nil {
+				return expiry, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Collect the expiry of system credentials, skipping those without a known expiry
+func collectCredentials() {
+	var store credentialsStore
+	resp, err := jenkinsCli.Requester.GetJSON("/credentials/store/system", &store, map[string]string{"depth": "2"})
+	if err != nil {
+		log.Errorf("Unable to get credentials: %s", err)
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("Unable to get credentials: status %d", resp.StatusCode)
+		return
+	}
+	for domain, credentials := range store.Domains {
+		for _, credential := range credentials.Credentials {
+			id, _ := credential["id"].(string)
+			expiry, ok := credentialExpiry(credential)
+			if id == "" || !ok {
+				continue
+			}
+			jenkinsCredentialExpiryTimestamp.WithLabelValues(id, domain).Set(float64(expiry.Unix()))
+		}
+	}
+	cacheAge.markRefreshed("credentials")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCollectCredentials(t *testing.T) {
+	newJenkinsFixture(t, map[string]string{
+		"/credentials/store/system/api/json": `{"domains": {
+			"_": {"credentials": [
+				{"id": "deploy-cert", "typeName": "Certificate", "expiry": 1767225600000},
+				{"id": "github-token", "typeName": "Secret text"}
+			]},
+			"internal": {"credentials": [
+				{"id": "vault-token", "typeName": "Vault Token", "expirationDate": "2026-12-31T00:00:00Z"}
+			]}
+		}}`,
+	})
+	jenkinsCredentialExpiryTimestamp.Reset()
+
+	collectCredentials()
+
+	if got := seriesCount(jenkinsCredentialExpiryTimestamp); got != 2 {
+		t.Fatalf("expected 2 expiring credentials, got %d", got)
+	}
+	if got := metricValue(t, jenkinsCredentialExpiryTimestamp.WithLabelValues("deploy-cert", "_")); got != 1767225600 {
+		t.Errorf("deploy-cert: expected 1767225600, got %v", got)
+	}
+	expected := float64(time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC).Unix())
+	if got := metricValue(t, jenkinsCredentialExpiryTimestamp.WithLabelValues("vault-token", "internal")); got != expected {
+		t.Errorf("vault-token: expected %v, got %v", expected, got)
+	}
+}
+
+func TestCollectCredentialsUnavailable(t *testing.T) {
+	newJenkinsFixture(t, map[string]string{})
+	saved := cacheAge
+	defer func() { cacheAge = saved }()
+	cacheAge = newCacheAgeCollector()
+
+	collectCredentials()
+
+	if _, found := cacheMetric(t, cacheAge, cacheAge.desc, "credentials"); found {
+		t.Error("expected the credentials group not to be marked refreshed after a failed fetch")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// Labels taken from the job description, created once the config is loaded
+var jenkinsJobDescriptionLabels *prometheus.GaugeVec
+
+// Register the description labels metric with the configured label keys
+func registerDescriptionLabels() {
+	jenkinsJobDescriptionLabels = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jenkins_job_description_labels",
+		Help: "Always 1, carries the key=value labels found in the job description",
+	}, append([]string{"jobname"}, config.Jenkins.DescriptionLabels...))
+	if err := prometheus.Register(jenkinsJobDescriptionLabels); err != nil {
+		log.Fatalf("Invalid descriptionLabels in configuration file: %s", err)
+	}
+}
+
+// Parse the key=value pairs following the marker in a job description, e.g.
+// "prometheus: export=true team=payments". The second value reports whether
+// the marker was found at all.
+func parseDescription(description string, marker string) (map[string]string, bool) {
+	for _, line := range strings.Split(description, "\n") {
+		i := strings.Index(line, marker)
+		if i < 0 {
+			continue
+		}
+		values := make(map[string]string)
+		for _, field := range strings.Fields(line[i+len(marker):]) {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) == 2 && kv[0] != "" {
+				values[kv[0]] = kv[1]
+			}
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// Decide whether a job is exported based on its description values.
+// Jobs without the marker or without an export key use the configured default.
+func exportedByDescription(values map[string]string, found bool) bool {
+	if export, ok := values["export"]; found && ok {
+		return export == "true"
+	}
+	return config.Jenkins.DescriptionDefaultExport
+}
+
+// Apply the description convention to a job. Returns false when the job
+// should not be exported.
+func collectDescriptionLabels(jobname string, description string) bool {
+	values, found := parseDescription(description, config.Jenkins.DescriptionMarker)
+	if !exportedByDescription(values, found) {
+		return false
+	}
+	labels := []string{jobname}
+	for _, key := range config.Jenkins.DescriptionLabels {
+		labels = append(labels, values[key])
+	}
+	jenkinsJobDescriptionLabels.WithLabelValues(labels...).Set(1)
+	return true
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestParseDescription(t *testing.T) {
+	values, found := parseDescription("Payments API\nprometheus: export=true team=payments tier=1 ignored", "prometheus:")
+	if !found {
+		t.Fatal("expected the marker to be found")
+	}
+	expected := map[string]string{"export": "true", "team": "payments", "tier": "1"}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, values)
+	}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, values[key])
+		}
+	}
+
+	if _, found := parseDescription("team=payments", "prometheus:"); found {
+		t.Error("expected no marker in a description without it")
+	}
+}
+
+func TestExportedByDescription(t *testing.T) {
+	defer func(saved bool) { config.Jenkins.DescriptionDefaultExport = saved }(config.Jenkins.DescriptionDefaultExport)
+
+	tests := []struct {
+		description   string
+		defaultExport bool
+		expected      bool
+	}{
+		{"prometheus: export=true team=payments", false, true},
+		{"prometheus: export=false team=payments", true, false},
+		{"prometheus: team=payments", true, true},
+		{"prometheus: team=payments", false, false},
+		{"no marker here", true, true},
+		{"no marker here", false, false},
+	}
+	for _, test := range tests {
+		config.Jenkins.DescriptionDefaultExport = test.defaultExport
+		values, found := parseDescription(test.description, "prometheus:")
+		if got := exportedByDescription(values, found); got != test.expected {
+			t.Errorf("%q with default %v: expected %v, got %v", test.description, test.defaultExport, test.expected, got)
+		}
+	}
+}
+
+func TestCollectJobSkippedByDescription(t *testing.T) {
+	defer func(saved jenkins) { config.Jenkins = saved }(config.Jenkins)
+	config.Jenkins.DescriptionMarker = "prometheus:"
+	config.Jenkins.DescriptionDefaultExport = false
+	config.Jenkins.UseRSSFallback = true
+	data, err := ioutil.ReadFile("testdata/rssAll.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newJenkinsFixture(t, map[string]string{
+		"/job/x/api/json":  `{"name": "x", "description": "prometheus: export=false"}`,
+		"/job/feed/rssAll": string(data),
+	})
+	jenkinsCompletedBuildSuccess.Reset()
+
+	if got := collectJob("x"); got != jobSkipped {
+		t.Errorf("expected a job not marked for export to be skipped, got %v", got)
+	}
+	// The feed can not tell whether the job is marked for export
+	if got := collectJob("feed"); got != jobFailed {
+		t.Errorf("expected no RSS fallback with a description marker, got %v", got)
+	}
+	if got := seriesCount(jenkinsCompletedBuildSuccess); got != 0 {
+		t.Errorf("expected no build series, got %d", got)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Replaces the values of the configured labels with a salted hash before exposition
+type hashingGatherer struct {
+	gatherer prometheus.Gatherer
+	labels   map[string]bool
+	salt     string
+}
+
+func newHashingGatherer(gatherer prometheus.Gatherer, labels []string, salt string) *hashingGatherer {
+	g := &hashingGatherer{gatherer: gatherer, labels: make(map[string]bool), salt: salt}
+	for _, label := range labels {
+		g.labels[label] = true
+	}
+	return g
+}
+
+// Stable salted hash of a label value
+func hashLabelValue(salt string, value string) string {
+	sum := sha256.Sum256([]byte(salt + value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Gather implements prometheus.Gatherer
+func (g *hashingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			for _, label := range metric.Label {
+				if g.labels[label.GetName()] {
+					value := hashLabelValue(g.salt, label.GetValue())
+					label.Value = &value
+				}
+			}
+		}
+	}
+	return families, err
+}
+
+// Metric families kept by the long-term export profile
+var longTermFamilies = map[string]bool{
+	"jenkins_build_success":          true,
+	"jenkins_build_duration_seconds": true,
+	"jenkins_build_timestamp":        true,
+	"jenkins_build_test_count":       true,
+}
+
+// Exports only job level metrics without the buildid label, keeping the
+// series of the latest build when a job has several
+type longTermGatherer struct {
+	gatherer prometheus.Gatherer
+}
+
+// Gather implements prometheus.Gatherer
+func (g *longTermGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+	var kept []*dto.MetricFamily
+	for _, family := range families {
+		if !longTermFamilies[family.GetName()] {
+			continue
+		}
+		// Index in metrics of each label set, and the build it was taken from
+		index := make(map[string]int)
+		var metrics []*dto.Metric
+		var builds []int
+		for _, metric := range family.Metric {
+			build := 0
+			var labels []*dto.LabelPair
+			var key []string
+			for _, label := range metric.Label {
+				if label.GetName() == "buildid" {
+					build, _ = strconv.Atoi(label.GetValue())
+					continue
+				}
+				labels = append(labels, label)
+				key = append(key, label.GetName()+"="+label.GetValue())
+			}
+			metric.Label = labels
+			k := strings.Join(key, ",")
+			if i, ok := index[k]; ok {
+				if build > builds[i] {
+					metrics[i] = metric
+					builds[i] = build
+				}
+				continue
+			}
+			index[k] = len(metrics)
+			metrics = append(metrics, metric)
+			builds = append(builds, build)
+		}
+		family.Metric = metrics
+		kept = append(kept, family)
+	}
+	return kept, err
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHashLabelValue(t *testing.T) {
+	first := hashLabelValue("salt", "payments-deploy")
+	if first != hashLabelValue("salt", "payments-deploy") {
+		t.Error("expected the hash to be stable")
+	}
+	if first == "payments-deploy" || first == hashLabelValue("other", "payments-deploy") {
+		t.Errorf("expected a salted hash, got %q", first)
+	}
+}
+
+func TestHashingGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "jenkins_build_success", Help: "test"}, []string{"jobname", "buildid"})
+	registry.MustRegister(gauge)
+	gauge.WithLabelValues("payments-deploy", "12").Set(1)
+
+	families, err := newHashingGatherer(registry, []string{"jobname"}, "salt").Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := families[0].Metric[0].Label
+	for _, label := range labels {
+		switch label.GetName() {
+		case "jobname":
+			if label.GetValue() != hashLabelValue("salt", "payments-deploy") {
+				t.Errorf("expected jobname to be hashed, got %q", label.GetValue())
+			}
+		case "buildid":
+			if label.GetValue() != "12" {
+				t.Errorf("expected buildid to be kept, got %q", label.GetValue())
+			}
+		}
+	}
+}
+
+func TestLongTermGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	success := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "jenkins_build_success", Help: "test"}, []string{"jobname", "buildid"})
+	culprit := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "jenkins_build_culprit", Help: "test"}, []string{"jobname", "buildid", "culprit"})
+	registry.MustRegister(success, culprit)
+	// Several builds of job1, as emitted by the RSS fallback
+	success.WithLabelValues("job1", "9").Set(1)
+	success.WithLabelValues("job1", "12").Set(0)
+	success.WithLabelValues("job1", "10").Set(1)
+	success.WithLabelValues("job2", "3").Set(1)
+	culprit.WithLabelValues("job1", "12", "alice").Set(1)
+
+	families, err := (&longTermGatherer{gatherer: registry}).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || families[0].GetName() != "jenkins_build_success" {
+		t.Fatalf("expected only jenkins_build_success, got %v", families)
+	}
+	values := make(map[string]float64)
+	for _, metric := range families[0].Metric {
+		for _, label := range metric.Label {
+			if label.GetName() == "buildid" {
+				t.Errorf("unexpected buildid label %q", label.GetValue())
+			}
+			if label.GetName() == "jobname" {
+				values[label.GetValue()] = metric.Gauge.GetValue()
+			}
+		}
+	}
+	if len(families[0].Metric) != 2 {
+		t.Errorf("expected one series per job, got %d", len(families[0].Metric))
+	}
+	if values["job1"] != 0 {
+		t.Errorf("expected the newest build of job1 (#12, failed) to be kept, got %v", values["job1"])
+	}
+	if values["job2"] != 1 {
+		t.Errorf("expected job2 to be kept, got %v", values["job2"])
+	}
+}
@@ -9,6 +9,7 @@ require (
 	github.com/lib/pq v1.7.0 // indirect
 	github.com/mattn/go-colorable v0.1.6 // indirect
 	github.com/prometheus/client_golang v1.7.1
+	github.com/prometheus/client_model v0.2.0
 	github.com/sirupsen/logrus v1.6.0
 	golang.org/x/net v0.0.0-20200602114024-627f9648deb9 // indirect
 )
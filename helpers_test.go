@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bndr/gojenkins"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Value of a single gauge or counter
+func metricValue(t *testing.T, metric prometheus.Metric) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Gauge != nil {
+		return m.Gauge.GetValue()
+	}
+	return m.Counter.GetValue()
+}
+
+// Number of series currently exported by a collector
+func seriesCount(c prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	n := 0
+	for range ch {
+		n++
+	}
+	return n
+}
+
+// Serve fixed JSON responses keyed by path and point jenkinsCli at them.
+// Unknown paths answer 404.
+func newJenkinsFixture(t *testing.T, responses map[string]string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	jenkinsCli = gojenkins.CreateJenkins(server.Client(), server.URL)
+}
+
+// Job and build pointing at the fixture
+func fixtureBuild(jobname string, number int64) (*gojenkins.Job, *gojenkins.Build) {
+	job := &gojenkins.Job{Jenkins: jenkinsCli, Raw: &gojenkins.JobResponse{Name: jobname}, Base: "/job/" + jobname}
+	build := &gojenkins.Build{Jenkins: jenkinsCli, Job: job, Raw: &gojenkins.BuildResponse{Number: number}, Base: job.Base + "/" + strconv.FormatInt(number, 10)}
+	return job, build
+}
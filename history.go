@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bndr/gojenkins"
+)
+
+// Recent builds of a job, newest first
+type buildHistory struct {
+	Builds []struct {
+		Number int64  `json:"number"`
+		Result string `json:"result"`
+	} `json:"builds"`
+}
+
+// Fetch the results of the last `depth` builds in a single request
+func getBuildHistory(job *gojenkins.Job, depth int) (buildHistory, error) {
+	var history buildHistory
+	resp, err := jenkinsCli.Requester.GetJSON(job.Base, &history, map[string]string{
+		"tree": fmt.Sprintf("builds[number,result]{0,%d}", depth),
+	})
+	if err != nil {
+		return history, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return history, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return history, nil
+}
+
+// Count the successful builds since the last failed one, ignoring running builds.
+// As for jenkins_build_success, only FAILURE counts as a failed build.
+func consecutiveSuccesses(history buildHistory) int {
+	streak := 0
+	for _, build := range history.Builds {
+		if build.Result == "" && streak == 0 {
+			continue
+		}
+		if build.Result == "FAILURE" {
+			break
+		}
+		streak++
+	}
+	return streak
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestConsecutiveSuccesses(t *testing.T) {
+	newJenkinsFixture(t, map[string]string{
+		"/job/x/api/json": `{"builds": [
+			{"number": 9, "result": null},
+			{"number": 8, "result": "SUCCESS"},
+			{"number": 7, "result": "UNSTABLE"},
+			{"number": 6, "result": "SUCCESS"},
+			{"number": 5, "result": "FAILURE"},
+			{"number": 4, "result": "SUCCESS"}
+		]}`,
+		"/job/broken/api/json": `{"builds": [
+			{"number": 3, "result": "FAILURE"},
+			{"number": 2, "result": "SUCCESS"}
+		]}`,
+	})
+
+	job, _ := fixtureBuild("x", 9)
+	history, err := getBuildHistory(job, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := consecutiveSuccesses(history); got != 3 {
+		t.Errorf("expected a streak of 3, got %d", got)
+	}
+
+	job, _ = fixtureBuild("broken", 3)
+	history, err = getBuildHistory(job, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := consecutiveSuccesses(history); got != 0 {
+		t.Errorf("expected the streak to reset after a failure, got %d", got)
+	}
+}
+
+func TestGetBuildHistoryNotFound(t *testing.T) {
+	newJenkinsFixture(t, map[string]string{})
+	job, _ := fixtureBuild("missing", 1)
+	if _, err := getBuildHistory(job, 10); err == nil {
+		t.Error("expected an error for a 404 response")
+	}
+}
@@ -19,6 +19,7 @@ import (
 var db *sql.DB
 var config Config
 var jenkinsCli *gojenkins.Jenkins
+var exportProfile string
 
 var jenkinsRunningBuild = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 	Name: "jenkins_running_build",
@@ -60,12 +61,88 @@ var jenkinsCompletedBuildTestCaseFailureAge = prometheus.NewGaugeVec(prometheus.
 	Help: "Age of the failed tests in this build",
 }, []string{"jobname", "buildid", "suite", "case", "status", "failedsince"})
 
+var jenkinsCompletedBuildTestCasesDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "jenkins_build_test_cases_dropped_total",
+	Help: "Number of test case series not exported because of the per build limit",
+}, []string{"jobname"})
+
+var jenkinsCompletedBuildTestSuitePassCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_build_test_suite_pass_count",
+	Help: "Number of passed tests in the test suite",
+}, []string{"jobname", "buildid", "suite"})
+
+var jenkinsCompletedBuildTestSuiteTotalCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_build_test_suite_total_count",
+	Help: "Number of tests in the test suite",
+}, []string{"jobname", "buildid", "suite"})
+
+var jenkinsCompletedBuildStageTestFailCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_build_stage_test_fail_count",
+	Help: "Number of failed tests recorded in the pipeline stage",
+}, []string{"jobname", "buildid", "stage"})
+
 var jenkinsCompletedBuildPipelineDurationSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 	Name: "jenkins_build_pipeline_duration_seconds",
 	Help: "Duration of each pipeline stage in seconds",
 }, []string{"jobname", "buildid", "id", "stage"})
 
-//Register metrics with Prometheus client
+var jenkinsCompletedBuildPromotion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_build_promotion",
+	Help: "1 if the build has achieved the promotion level, 0 otherwise",
+}, []string{"jobname", "buildid", "level"})
+
+var jenkinsCompletedBuildBranchEvent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_build_branch_event",
+	Help: "1 for the branch event (push, pr, indexing) that triggered the multibranch build",
+}, []string{"jobname", "buildid", "event"})
+
+var jenkinsCompletedBuildUpstreamDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_build_upstream_depth",
+	Help: "Number of transitive upstream builds found through the build causes",
+}, []string{"jobname", "buildid"})
+
+var jenkinsCompletedBuildCulprit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_build_culprit",
+	Help: "1 for each committer blamed for the failed build",
+}, []string{"jobname", "buildid", "culprit"})
+
+var jenkinsJobConsecutiveSuccesses = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_job_consecutive_successes",
+	Help: "Number of consecutive successful builds, up to the configured history depth",
+}, []string{"jobname"})
+
+var jenkinsQueueItemUnrunnable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_queue_item_unrunnable",
+	Help: "1 if the queue item can not run because no node matches its label, 0 otherwise",
+}, []string{"jobname", "id"})
+
+var jenkinsQueueItemConcurrencyBlockSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_build_concurrency_block_seconds",
+	Help: "Seconds the queued build has been blocked by the job concurrency limit",
+}, []string{"jobname", "id"})
+
+// Without labels, the vector lets the metric stay absent until a conclusive check
+var jenkinsUpdateCenterReachable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_update_center_reachable",
+	Help: "1 if Jenkins can reach the update center, 0 otherwise",
+}, nil)
+
+var jenkinsCredentialExpiryTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "jenkins_credential_expiry_timestamp_seconds",
+	Help: "Expiry of the credential as a Unix timestamp",
+}, []string{"id", "domain"})
+
+var jenkinsExporterCollectionInProgress = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "jenkins_exporter_collection_in_progress",
+	Help: "1 while a job is being collected, 0 otherwise",
+})
+
+var jenkinsExporterStartTime = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "jenkins_exporter_start_time_seconds",
+	Help: "Start time of the exporter as a Unix timestamp",
+})
+
+// Register metrics with Prometheus client
 func init() {
 	prometheus.MustRegister(jenkinsRunningBuild)
 	prometheus.MustRegister(jenkinsRunningBuildElapsedTime)
@@ -76,12 +153,29 @@ func init() {
 	prometheus.MustRegister(jenkinsCompletedBuildTestCount)
 	prometheus.MustRegister(jenkinsCompletedBuildPipelineDurationSeconds)
 	prometheus.MustRegister(jenkinsCompletedBuildTestCaseFailureAge)
+	prometheus.MustRegister(jenkinsCompletedBuildTestCasesDropped)
+	prometheus.MustRegister(jenkinsCompletedBuildTestSuitePassCount)
+	prometheus.MustRegister(jenkinsCompletedBuildTestSuiteTotalCount)
+	prometheus.MustRegister(jenkinsCompletedBuildStageTestFailCount)
+	prometheus.MustRegister(jenkinsCompletedBuildPromotion)
+	prometheus.MustRegister(jenkinsCompletedBuildBranchEvent)
+	prometheus.MustRegister(jenkinsCompletedBuildUpstreamDepth)
+	prometheus.MustRegister(jenkinsCompletedBuildCulprit)
+	prometheus.MustRegister(jenkinsJobConsecutiveSuccesses)
+	prometheus.MustRegister(jenkinsQueueItemUnrunnable)
+	prometheus.MustRegister(jenkinsQueueItemConcurrencyBlockSeconds)
+	prometheus.MustRegister(jenkinsCredentialExpiryTimestamp)
+	prometheus.MustRegister(jenkinsExporterCollectionInProgress)
+	prometheus.MustRegister(cacheAge)
+	prometheus.MustRegister(jenkinsExporterStartTime)
+	jenkinsExporterStartTime.SetToCurrentTime()
 }
 
-// Load configuration
-func init() {
+// Load configuration, called from main so tests can run without flags or a config file
+func configure() {
 	debugFlag := flag.Bool("debug", false, "Sets log level to debug.")
 	configFileFlag := flag.String("config", "./config.toml", "Path to config file")
+	flag.StringVar(&exportProfile, "profile", "full", "Export profile: full, or longterm for job level metrics without buildid")
 	flag.Parse()
 	// Setting logger to debug level when debug flag was set.
 	if *debugFlag == true {
@@ -95,11 +189,30 @@ func init() {
 	} else {
 		log.Fatal("Please provide a config file with `-config <yourconfig>` or just create `config.toml` in this directory")
 	}
+	if exportProfile != "full" && exportProfile != "longterm" {
+		log.Fatalf("Unknown export profile: %s", exportProfile)
+	}
 	// Make sure update interval has a default value
 	log.Debugf("Configuration: %+v", config)
 	if config.Jenkins.UpdateInterval <= 0 {
 		config.Jenkins.UpdateInterval = 1800 // 30 mins
 	}
+	if config.Jenkins.HistoryDepth <= 0 {
+		config.Jenkins.HistoryDepth = 10
+	}
+	if config.Jenkins.MaxUpstreamDepth <= 0 {
+		config.Jenkins.MaxUpstreamDepth = 5
+	}
+	// Without a salt the hashed values can be reversed with a dictionary
+	if len(config.Jenkins.HashLabels) > 0 && config.Jenkins.HashSalt == "" {
+		log.Fatal("Please set hashSalt in the configuration file when hashLabels is used")
+	}
+	if config.Jenkins.CollectUpdateCenter {
+		prometheus.MustRegister(jenkinsUpdateCenterReachable)
+	}
+	if config.Jenkins.DescriptionMarker != "" {
+		registerDescriptionLabels()
+	}
 }
 
 // Fetch metrics from Jenkins API
@@ -119,10 +232,14 @@ func updateMetrics() {
 	defer client.CloseIdleConnections()
 	_, err := jenkinsCli.Init()
 	if err != nil {
-		log.Errorf("Unable to connect to Jenkins", err)
+		log.Errorf("Unable to connect to Jenkins: %s", err)
 		return
 	}
 
+	// Scrapes are no longer served from cache until the refresh completes
+	cacheAge.startRefresh()
+	defer cacheAge.finishRefresh()
+
 	// Reset all metrics
 	jenkinsRunningBuild.Reset()
 	jenkinsRunningBuildElapsedTime.Reset()
@@ -132,7 +249,22 @@ func updateMetrics() {
 	jenkinsCompletedBuildTestCount.Reset()
 	jenkinsCompletedBuildPipelineDurationSeconds.Reset()
 	jenkinsCompletedBuildTestCaseFailureAge.Reset()
+	jenkinsCompletedBuildTestSuitePassCount.Reset()
+	jenkinsCompletedBuildTestSuiteTotalCount.Reset()
+	jenkinsCompletedBuildStageTestFailCount.Reset()
 	jenkinsCompletedBuildTimestamp.Reset()
+	jenkinsCompletedBuildPromotion.Reset()
+	jenkinsCompletedBuildBranchEvent.Reset()
+	jenkinsCompletedBuildUpstreamDepth.Reset()
+	jenkinsCompletedBuildCulprit.Reset()
+	jenkinsJobConsecutiveSuccesses.Reset()
+	jenkinsQueueItemUnrunnable.Reset()
+	jenkinsQueueItemConcurrencyBlockSeconds.Reset()
+	jenkinsUpdateCenterReachable.Reset()
+	jenkinsCredentialExpiryTimestamp.Reset()
+	if jenkinsJobDescriptionLabels != nil {
+		jenkinsJobDescriptionLabels.Reset()
+	}
 
 	/*
 		------------------------------
@@ -140,136 +272,256 @@ func updateMetrics() {
 		------------------------------
 	*/
 
+	collectedJobs = make(map[string]bool)
 	for _, jobname := range config.Jenkins.Jobs {
-
-		job, err := jenkinsCli.GetJob(jobname)
-		if err != nil {
-			log.Errorf("Job Does Not Exist", err)
+		switch collectJob(jobname) {
+		case jobFailed:
+			// A job that can not be collected aborts the cycle
 			return
+		case jobCollected:
+			collectedJobs[jobname] = true
 		}
+	}
+	if len(collectedJobs) > 0 {
+		cacheAge.markRefreshed("jobs")
+	}
 
-		// Get Last Completed build
-		lastCompletedBuild, err := job.GetLastCompletedBuild()
-		if err != nil {
-			log.Errorf("Unable to collect metrics for job: "+jobname+" - unable to get Last Completed Build", err)
-			return
-		}
-		// Get Last Build (can be a running build)
-		lastBuild, err := job.GetLastBuild()
-		if err != nil {
-			log.Errorf("Unable to collect metrics for job: "+jobname+" - unable to get Last Build", err)
-			return
+	// Queue items of the collected jobs
+	collectQueue()
+
+	// Update center connectivity
+	if config.Jenkins.CollectUpdateCenter {
+		collectUpdateCenter()
+	}
+
+	// Credentials expiry
+	if config.Jenkins.CollectCredentials {
+		collectCredentials()
+	}
+}
+
+// Outcome of collecting a single job
+type jobOutcome int
+
+const (
+	jobFailed jobOutcome = iota
+	jobCollected
+	// Left out by the description filter
+	jobSkipped
+)
+
+// Collect metrics for a single job
+func collectJob(jobname string) jobOutcome {
+	jenkinsExporterCollectionInProgress.Inc()
+	defer jenkinsExporterCollectionInProgress.Dec()
+
+	job, err := jenkinsCli.GetJob(jobname)
+	if err != nil {
+		log.Errorf("Job Does Not Exist: %s", err)
+		// The feed does not carry the description, so filtered jobs can not use it
+		if config.Jenkins.UseRSSFallback && config.Jenkins.DescriptionMarker == "" && collectJobFromFeed(jobname) {
+			return jobCollected
 		}
+		return jobFailed
+	}
 
-		// Common labels to various metrics
-		commonArgs := []string{
-			job.GetName(),
-			strconv.Itoa(int(lastCompletedBuild.GetBuildNumber())),
+	// Filter and label jobs using the key=value convention in their description
+	if config.Jenkins.DescriptionMarker != "" {
+		if !collectDescriptionLabels(job.GetName(), job.GetDescription()) {
+			log.Debugf("Skipping job not marked for export: %s", jobname)
+			return jobSkipped
 		}
+	}
 
-		// Simple metrics - build timestamp and duration
-		jenkinsCompletedBuildDurationSeconds.WithLabelValues(commonArgs...).Set(float64(lastCompletedBuild.GetDuration() / 1000))
-		jenkinsCompletedBuildTimestamp.WithLabelValues(commonArgs...).Set(float64(lastCompletedBuild.GetTimestamp().Local().Unix()))
+	// Get Last Completed build
+	lastCompletedBuild, err := job.GetLastCompletedBuild()
+	if err != nil {
+		log.Errorf("Unable to collect metrics for job: "+jobname+" - unable to get Last Completed Build", err)
+		return jobFailed
+	}
+	// Get Last Build (can be a running build)
+	lastBuild, err := job.GetLastBuild()
+	if err != nil {
+		log.Errorf("Unable to collect metrics for job: "+jobname+" - unable to get Last Build", err)
+		return jobFailed
+	}
 
-		// Simple metrics - test counts
-		resultset, err := lastCompletedBuild.GetResultSet()
-		jenkinsCompletedBuildTestCount.WithLabelValues(append(commonArgs, "fail")...).Set(float64(resultset.FailCount))
-		jenkinsCompletedBuildTestCount.WithLabelValues(append(commonArgs, "skip")...).Set(float64(resultset.SkipCount))
-		jenkinsCompletedBuildTestCount.WithLabelValues(append(commonArgs, "pass")...).Set(float64(resultset.PassCount))
+	// Common labels to various metrics
+	commonArgs := []string{
+		job.GetName(),
+		strconv.Itoa(int(lastCompletedBuild.GetBuildNumber())),
+	}
 
-		// Is there any build running?
-		isRunning := func(running bool, err error) float64 {
-			if running {
-				return 1
-			}
-			return 0
-		}(job.IsRunning())
+	// Simple metrics - build timestamp and duration
+	jenkinsCompletedBuildDurationSeconds.WithLabelValues(commonArgs...).Set(float64(lastCompletedBuild.GetDuration() / 1000))
+	jenkinsCompletedBuildTimestamp.WithLabelValues(commonArgs...).Set(float64(lastCompletedBuild.GetTimestamp().Local().Unix()))
 
-		// Is the build good (without errors so far)?
-		isGood := func(isGood bool) string {
-			if isGood {
-				return "1"
-			}
-			return "0"
-		}(lastBuild.IsGood())
-		jenkinsRunningBuild.WithLabelValues(
+	// Simple metrics - test counts
+	resultset, err := lastCompletedBuild.GetResultSet()
+	jenkinsCompletedBuildTestCount.WithLabelValues(append(commonArgs, "fail")...).Set(float64(resultset.FailCount))
+	jenkinsCompletedBuildTestCount.WithLabelValues(append(commonArgs, "skip")...).Set(float64(resultset.SkipCount))
+	jenkinsCompletedBuildTestCount.WithLabelValues(append(commonArgs, "pass")...).Set(float64(resultset.PassCount))
+
+	// Is there any build running?
+	isRunning := func(running bool, err error) float64 {
+		if running {
+			return 1
+		}
+		return 0
+	}(job.IsRunning())
+
+	// Is the build good (without errors so far)?
+	isGood := func(isGood bool) string {
+		if isGood {
+			return "1"
+		}
+		return "0"
+	}(lastBuild.IsGood())
+	jenkinsRunningBuild.WithLabelValues(
+		job.GetName(),
+		strconv.Itoa(int(lastBuild.GetBuildNumber())),
+		isGood,
+	).Set(isRunning)
+
+	// If there is a job running, add metric with elapsed time
+	if isRunning == 1 {
+		var elapsedTime int64 = 0
+		livePipe, _ := job.GetPipelineRun(strconv.Itoa(int(lastBuild.GetBuildNumber())))
+		for _, stage := range livePipe.Stages {
+			elapsedTime += stage.Duration / 1000
+			jenkinsRunningBuildPipelineStatus.WithLabelValues(
+				job.GetName(),
+				strconv.Itoa(int(lastBuild.GetBuildNumber())),
+				fmt.Sprintf("%03s", stage.ID),
+				stage.Name).Set(
+				func() float64 {
+					switch stage.Status {
+					case "SUCCESS":
+						return 0
+					case "IN_PROGRESS":
+						return 1
+					case "UNSTABLE":
+						return 2
+					case "FAILED":
+						return 3
+					}
+					return -1
+				}())
+		}
+
+		jenkinsRunningBuildElapsedTime.WithLabelValues(
 			job.GetName(),
 			strconv.Itoa(int(lastBuild.GetBuildNumber())),
 			isGood,
-		).Set(isRunning)
-
-		// If there is a job running, add metric with elapsed time
-		if isRunning == 1 {
-			var elapsedTime int64 = 0
-			livePipe, _ := job.GetPipelineRun(strconv.Itoa(int(lastBuild.GetBuildNumber())))
-			for _, stage := range livePipe.Stages {
-				elapsedTime += stage.Duration / 1000
-				jenkinsRunningBuildPipelineStatus.WithLabelValues(
-					job.GetName(),
-					strconv.Itoa(int(lastBuild.GetBuildNumber())),
-					fmt.Sprintf("%03s", stage.ID),
-					stage.Name).Set(
-					func() float64 {
-						switch stage.Status {
-						case "SUCCESS":
-							return 0
-						case "IN_PROGRESS":
-							return 1
-						case "UNSTABLE":
-							return 2
-						case "FAILED":
-							return 3
-						}
-						return -1
-					}())
+		).Set(float64(elapsedTime))
+	}
+
+	// Build result
+	jenkinsCompletedBuildSuccess.WithLabelValues(commonArgs...).Set(
+		func(result string) float64 {
+			if result == "FAILURE" {
+				return 0
 			}
+			return 1
+		}(lastCompletedBuild.GetResult()))
 
-			jenkinsRunningBuildElapsedTime.WithLabelValues(
-				job.GetName(),
-				strconv.Itoa(int(lastBuild.GetBuildNumber())),
-				isGood,
-			).Set(float64(elapsedTime))
-		}
+	// Failed tests per pipeline stage, when the stage is recorded with the results
+	collectStageTests(commonArgs, lastCompletedBuild)
 
-		// Build result
-		jenkinsCompletedBuildSuccess.WithLabelValues(commonArgs...).Set(
-			func(result string) float64 {
-				if result == "FAILURE" {
-					return 0
-				}
-				return 1
-			}(lastCompletedBuild.GetResult()))
-
-		// Iterate over failed and regression tests
-		for _, suite := range resultset.Suites {
-			for _, testcase := range suite.Cases {
-				if !testcase.Skipped && testcase.Status != "PASSED" {
-					jenkinsCompletedBuildTestCaseFailureAge.WithLabelValues(
-						append(commonArgs,
-							suite.Name,
-							testcase.Name,
-							testcase.Status,
-							strconv.Itoa(int(testcase.FailedSince)),
-						)...).Set(float64(testcase.Age))
+	// Committers blamed for a failed build
+	collectCulprits(commonArgs, lastCompletedBuild)
+
+	// Failed test cases and suite level counts
+	collectTestSuites(commonArgs, resultset)
+
+	// Last completed pipeline build duration
+	lastCompletedPipeline, err := job.GetPipelineRun(strconv.Itoa(int(lastCompletedBuild.GetBuildNumber())))
+	for _, stage := range lastCompletedPipeline.Stages {
+		jenkinsCompletedBuildPipelineDurationSeconds.WithLabelValues(
+			job.GetName(),
+			strconv.Itoa(int(lastCompletedBuild.GetBuildNumber())),
+			fmt.Sprintf("%03s", stage.ID),
+			stage.Name,
+		).Set(float64(stage.Duration / 1000))
+	}
+
+	// Green streak over the recent builds
+	if history, err := getBuildHistory(job, config.Jenkins.HistoryDepth); err == nil {
+		jenkinsJobConsecutiveSuccesses.WithLabelValues(job.GetName()).Set(float64(consecutiveSuccesses(history)))
+	} else {
+		log.Errorf("Unable to get build history for job %s: %s", jobname, err)
+	}
+
+	// Branch event that triggered the last completed build (multibranch only)
+	collectBranchEvent(commonArgs, lastCompletedBuild)
+
+	// Upstream builds chained through the causes of the last completed build
+	if config.Jenkins.CollectUpstreamDepth {
+		jenkinsCompletedBuildUpstreamDepth.WithLabelValues(commonArgs...).Set(
+			float64(upstreamDepth(lastCompletedBuild, config.Jenkins.MaxUpstreamDepth)))
+	}
+
+	// Promotion levels achieved by the last completed build
+	if config.Jenkins.CollectPromotions {
+		collectPromotions(job, lastCompletedBuild)
+	}
+	log.Debugf("Finished collecting metrics for job: %s", jobname)
+	return jobCollected
+}
+
+// Collect the committers blamed for a failed build, duplicates share the same series
+func collectCulprits(commonArgs []string, build *gojenkins.Build) {
+	if build.GetResult() != "FAILURE" {
+		return
+	}
+	for _, culprit := range build.GetCulprits() {
+		jenkinsCompletedBuildCulprit.WithLabelValues(append(commonArgs, culprit.FullName)...).Set(1)
+	}
+}
+
+// Last build counted in jenkins_build_test_cases_dropped_total, by job
+var testCasesDroppedBuild = make(map[string]string)
+
+// Collect the failed test cases and the suite level pass counts of a build
+func collectTestSuites(commonArgs []string, resultset *gojenkins.TestResult) {
+	// Iterate over failed and regression tests, up to MaxTestCasesPerBuild series
+	testCases, droppedTestCases := 0, 0
+	for _, suite := range resultset.Suites {
+		passed := 0
+		for _, testcase := range suite.Cases {
+			if testcase.Status == "PASSED" || testcase.Status == "FIXED" {
+				passed++
+			}
+			if !testcase.Skipped && testcase.Status != "PASSED" {
+				if config.Jenkins.MaxTestCasesPerBuild > 0 && testCases >= config.Jenkins.MaxTestCasesPerBuild {
+					droppedTestCases++
+					continue
 				}
+				testCases++
+				jenkinsCompletedBuildTestCaseFailureAge.WithLabelValues(
+					append(commonArgs,
+						suite.Name,
+						testcase.Name,
+						testcase.Status,
+						strconv.Itoa(int(testcase.FailedSince)),
+					)...).Set(float64(testcase.Age))
 			}
 		}
 
-		// Last completed pipeline build duration
-		lastCompletedPipeline, err := job.GetPipelineRun(strconv.Itoa(int(lastCompletedBuild.GetBuildNumber())))
-		for _, stage := range lastCompletedPipeline.Stages {
-			jenkinsCompletedBuildPipelineDurationSeconds.WithLabelValues(
-				job.GetName(),
-				strconv.Itoa(int(lastCompletedBuild.GetBuildNumber())),
-				fmt.Sprintf("%03s", stage.ID),
-				stage.Name,
-			).Set(float64(stage.Duration / 1000))
-		}
-		log.Debugf("Finished collecting metrics for job: %s", jobname)
+		// Suite level pass ratio
+		jenkinsCompletedBuildTestSuitePassCount.WithLabelValues(append(commonArgs, suite.Name)...).Add(float64(passed))
+		jenkinsCompletedBuildTestSuiteTotalCount.WithLabelValues(append(commonArgs, suite.Name)...).Add(float64(len(suite.Cases)))
+	}
+	// The same build is seen on every poll, count its dropped cases only once
+	if droppedTestCases > 0 && testCasesDroppedBuild[commonArgs[0]] != commonArgs[1] {
+		log.Debugf("Dropped %d test case series for job %s, over the limit of %d", droppedTestCases, commonArgs[0], config.Jenkins.MaxTestCasesPerBuild)
+		jenkinsCompletedBuildTestCasesDropped.WithLabelValues(commonArgs[0]).Add(float64(droppedTestCases))
+		testCasesDroppedBuild[commonArgs[0]] = commonArgs[1]
 	}
 }
 
 func main() {
+	configure()
 
 	// Poll Jenkins API on a regular interval
 	go func() {
@@ -279,8 +531,22 @@ func main() {
 		}
 	}()
 
+	// Keep only job level metrics for long-term storage
+	var gatherer prometheus.Gatherer = prometheus.DefaultGatherer
+	if exportProfile == "longterm" {
+		gatherer = &longTermGatherer{gatherer: gatherer}
+	}
+
+	// Hash sensitive label values before they are exposed
+	if len(config.Jenkins.HashLabels) > 0 {
+		gatherer = newHashingGatherer(gatherer, config.Jenkins.HashLabels, config.Jenkins.HashSalt)
+	}
+
 	// Start http requests
-	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/metrics", promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}),
+	))
 	log.Info("Serving metrics on :9118/metrics")
 	log.Fatal(http.ListenAndServe(":9118", nil))
 
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/bndr/gojenkins"
+)
+
+func TestCollectJobReleasesCollectionGauge(t *testing.T) {
+	newJenkinsFixture(t, map[string]string{
+		"/job/x/api/json":    `{"name": "x", "url": "/job/x", "lastBuild": {"number": 12}, "lastCompletedBuild": {"number": 12}}`,
+		"/job/x/12/api/json": `{"number": 12, "result": "SUCCESS", "timestamp": 1600000000000, "duration": 60000}`,
+	})
+	jenkinsCompletedBuildSuccess.Reset()
+
+	if collectJob("x") != jobCollected {
+		t.Fatal("expected the job to be collected")
+	}
+	if got := metricValue(t, jenkinsCompletedBuildSuccess.WithLabelValues("x", "12")); got != 1 {
+		t.Errorf("expected the build result to be exported, got %v", got)
+	}
+	if got := metricValue(t, jenkinsExporterCollectionInProgress); got != 0 {
+		t.Errorf("expected no collection in progress after a collected job, got %v", got)
+	}
+
+	if collectJob("missing") != jobFailed {
+		t.Error("expected a missing job not to be collected")
+	}
+	if got := metricValue(t, jenkinsExporterCollectionInProgress); got != 0 {
+		t.Errorf("expected no collection in progress after a failed job, got %v", got)
+	}
+}
+
+// Test report read from testdata
+func loadTestResult(t *testing.T, name string) *gojenkins.TestResult {
+	t.Helper()
+	data, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resultset gojenkins.TestResult
+	if err := json.Unmarshal(data, &resultset); err != nil {
+		t.Fatal(err)
+	}
+	return &resultset
+}
+
+func TestCollectTestSuites(t *testing.T) {
+	jenkinsCompletedBuildTestSuitePassCount.Reset()
+	jenkinsCompletedBuildTestSuiteTotalCount.Reset()
+	jenkinsCompletedBuildTestCaseFailureAge.Reset()
+
+	collectTestSuites([]string{"job1", "12"}, loadTestResult(t, "testReport.json"))
+
+	tests := []struct {
+		suite  string
+		passed float64
+		total  float64
+	}{
+		{"unit", 4, 4},
+		{"integration", 1, 4},
+	}
+	for _, test := range tests {
+		if got := metricValue(t, jenkinsCompletedBuildTestSuitePassCount.WithLabelValues("job1", "12", test.suite)); got != test.passed {
+			t.Errorf("%s: expected %v passed, got %v", test.suite, test.passed, got)
+		}
+		if got := metricValue(t, jenkinsCompletedBuildTestSuiteTotalCount.WithLabelValues("job1", "12", test.suite)); got != test.total {
+			t.Errorf("%s: expected %v total, got %v", test.suite, test.total, got)
+		}
+	}
+}
+
+func TestCollectCulprits(t *testing.T) {
+	jenkinsCompletedBuildCulprit.Reset()
+	build := &gojenkins.Build{Raw: &gojenkins.BuildResponse{
+		Number: 12,
+		Result: "FAILURE",
+		Culprits: []gojenkins.Culprit{
+			{FullName: "alice"},
+			{FullName: "bob"},
+			{FullName: "alice"},
+		},
+	}}
+
+	collectCulprits([]string{"job1", "12"}, build)
+
+	if got := seriesCount(jenkinsCompletedBuildCulprit); got != 2 {
+		t.Fatalf("expected 2 distinct culprits, got %d", got)
+	}
+	for _, culprit := range []string{"alice", "bob"} {
+		if got := metricValue(t, jenkinsCompletedBuildCulprit.WithLabelValues("job1", "12", culprit)); got != 1 {
+			t.Errorf("%s: expected 1, got %v", culprit, got)
+		}
+	}
+
+	jenkinsCompletedBuildCulprit.Reset()
+	build.Raw.Result = "SUCCESS"
+	collectCulprits([]string{"job1", "12"}, build)
+	if got := seriesCount(jenkinsCompletedBuildCulprit); got != 0 {
+		t.Errorf("expected no culprits on a successful build, got %d", got)
+	}
+}
+
+func TestExporterStartTime(t *testing.T) {
+	started := metricValue(t, jenkinsExporterStartTime)
+	now := float64(time.Now().UnixNano()) / 1e9
+	if started <= 0 || started > now || now-started > 3600 {
+		t.Errorf("expected a start time within the last hour, got %v (now %v)", started, now)
+	}
+}
+
+func TestCollectTestSuitesOverLimit(t *testing.T) {
+	defer func(saved int) { config.Jenkins.MaxTestCasesPerBuild = saved }(config.Jenkins.MaxTestCasesPerBuild)
+	config.Jenkins.MaxTestCasesPerBuild = 1
+	jenkinsCompletedBuildTestCaseFailureAge.Reset()
+	resultset := loadTestResult(t, "testReport.json")
+
+	// 3 non passing cases (FIXED, FAILED, REGRESSION) for a limit of 1
+	collectTestSuites([]string{"capped", "12"}, resultset)
+
+	if got := seriesCount(jenkinsCompletedBuildTestCaseFailureAge); got != 1 {
+		t.Errorf("expected 1 test case series, got %d", got)
+	}
+	if got := metricValue(t, jenkinsCompletedBuildTestCasesDropped.WithLabelValues("capped")); got != 2 {
+		t.Errorf("expected 2 dropped test cases, got %v", got)
+	}
+
+	// Polling the same build again does not count its cases twice
+	collectTestSuites([]string{"capped", "12"}, resultset)
+	if got := metricValue(t, jenkinsCompletedBuildTestCasesDropped.WithLabelValues("capped")); got != 2 {
+		t.Errorf("expected the same build to be counted once, got %v", got)
+	}
+
+	collectTestSuites([]string{"capped", "13"}, resultset)
+	if got := metricValue(t, jenkinsCompletedBuildTestCasesDropped.WithLabelValues("capped")); got != 4 {
+		t.Errorf("expected a new build to add its dropped cases, got %v", got)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bndr/gojenkins"
+	log "github.com/sirupsen/logrus"
+)
+
+// Promotion processes configured on a job (Promoted Builds plugin)
+type promotionProcesses struct {
+	Processes []struct {
+		Name string `json:"name"`
+	} `json:"processes"`
+}
+
+// Promotions achieved by a single build (Promoted Builds plugin)
+type buildPromotions struct {
+	Promotions []struct {
+		Name string `json:"name"`
+	} `json:"promotions"`
+}
+
+// Set one series per promotion level of the job, 1 if the build has achieved it.
+// Jobs without the Promoted Builds plugin are skipped.
+func collectPromotions(job *gojenkins.Job, build *gojenkins.Build) {
+	var processes promotionProcesses
+	resp, err := jenkinsCli.Requester.GetJSON(job.Base+"/promotion", &processes, nil)
+	if err != nil {
+		log.Errorf("Unable to get promotion processes for job %s: %s", job.GetName(), err)
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Debugf("Promoted Builds plugin not available for job %s", job.GetName())
+		return
+	}
+
+	var promotions buildPromotions
+	resp, err = jenkinsCli.Requester.GetJSON(build.Base+"/promotion", &promotions, nil)
+	if err != nil {
+		log.Errorf("Unable to get promotions for job %s: %s", job.GetName(), err)
+		return
+	}
+	achieved := make(map[string]bool)
+	if resp.StatusCode == http.StatusOK {
+		for _, promotion := range promotions.Promotions {
+			achieved[promotion.Name] = true
+		}
+	}
+
+	for _, process := range processes.Processes {
+		jenkinsCompletedBuildPromotion.WithLabelValues(
+			job.GetName(),
+			strconv.Itoa(int(build.GetBuildNumber())),
+			process.Name,
+		).Set(func(ok bool) float64 {
+			if ok {
+				return 1
+			}
+			return 0
+		}(achieved[process.Name]))
+	}
+}
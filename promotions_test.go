@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestCollectPromotions(t *testing.T) {
+	newJenkinsFixture(t, map[string]string{
+		"/job/x/promotion/api/json":   `{"processes": [{"name": "QA"}, {"name": "Release"}]}`,
+		"/job/x/7/promotion/api/json": `{"promotions": [{"name": "QA"}]}`,
+	})
+	jenkinsCompletedBuildPromotion.Reset()
+	job, build := fixtureBuild("x", 7)
+
+	collectPromotions(job, build)
+
+	if got := seriesCount(jenkinsCompletedBuildPromotion); got != 2 {
+		t.Fatalf("expected 2 promotion series, got %d", got)
+	}
+	if got := metricValue(t, jenkinsCompletedBuildPromotion.WithLabelValues("x", "7", "QA")); got != 1 {
+		t.Errorf("QA: expected 1, got %v", got)
+	}
+	if got := metricValue(t, jenkinsCompletedBuildPromotion.WithLabelValues("x", "7", "Release")); got != 0 {
+		t.Errorf("Release: expected 0, got %v", got)
+	}
+}
+
+func TestCollectPromotionsWithoutPlugin(t *testing.T) {
+	newJenkinsFixture(t, map[string]string{})
+	jenkinsCompletedBuildPromotion.Reset()
+	job, build := fixtureBuild("x", 7)
+
+	collectPromotions(job, build)
+
+	if got := seriesCount(jenkinsCompletedBuildPromotion); got != 0 {
+		t.Errorf("expected no series without the plugin, got %d", got)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Fragments of the queue `why` text meaning no agent can ever run the item
+var unrunnableReasons = []string{
+	"there are no nodes with the label",
+	"doesn't have label",
+}
+
+// Detect queue items that will never run because no node matches their label
+func isUnrunnable(why string) bool {
+	why = strings.ToLower(strings.Replace(why, "’", "'", -1))
+	for _, reason := range unrunnableReasons {
+		if strings.Contains(why, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fragments of the queue `why` text meaning the item waits for a concurrency limit
+var concurrencyBlockReasons = []string{
+	"is already in progress",
+	"already running",
+	"concurrent builds",
+}
+
+// Detect queue items blocked because the job reached its concurrent builds limit
+func isConcurrencyBlocked(why string) bool {
+	why = strings.ToLower(why)
+	for _, reason := range concurrencyBlockReasons {
+		if strings.Contains(why, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// When each queue item got blocked by a concurrency limit, by queue id
+var concurrencyBlockedSince = make(map[int64]time.Time)
+
+// Time of the previous queue collection, zero before the first one
+var lastQueueCollection time.Time
+
+// Jobs collected in the current cycle, jobs left out by the description filter are not included
+var collectedJobs = make(map[string]bool)
+
+// Collect metrics for queue items of the collected jobs
+func collectQueue() {
+	queue, err := jenkinsCli.GetQueue()
+	if err != nil {
+		log.Errorf("Unable to get Jenkins queue: %s", err)
+		return
+	}
+
+	now := time.Now()
+	blockedSince := make(map[int64]time.Time)
+
+	// Iterate over the raw items, Queue.Tasks() shares one item across all tasks
+	for _, item := range queue.Raw.Items {
+		if !collectedJobs[item.Task.Name] {
+			continue
+		}
+		jenkinsQueueItemUnrunnable.WithLabelValues(
+			item.Task.Name,
+			strconv.Itoa(int(item.ID)),
+		).Set(func(unrunnable bool) float64 {
+			if unrunnable {
+				return 1
+			}
+			return 0
+		}(isUnrunnable(item.Why)))
+
+		// Time spent waiting for a previous build of the job to finish. An item first
+		// seen blocked got blocked after it was queued and, if it was already in the
+		// queue, after the previous collection found it not blocked.
+		if item.Blocked && isConcurrencyBlocked(item.Why) {
+			since, ok := concurrencyBlockedSince[item.ID]
+			if !ok {
+				since = time.Unix(0, item.InQueueSince*int64(time.Millisecond))
+				if lastQueueCollection.After(since) {
+					since = lastQueueCollection
+				}
+			}
+			blockedSince[item.ID] = since
+			jenkinsQueueItemConcurrencyBlockSeconds.WithLabelValues(
+				item.Task.Name,
+				strconv.Itoa(int(item.ID)),
+			).Set(now.Sub(since).Seconds())
+		}
+	}
+	// Forget items that left the queue or are no longer blocked
+	concurrencyBlockedSince = blockedSince
+	lastQueueCollection = now
+	cacheAge.markRefreshed("queue")
+}
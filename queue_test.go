@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIsUnrunnable(t *testing.T) {
+	tests := []struct {
+		why      string
+		expected bool
+	}{
+		{"There are no nodes with the label ‘windows’", true},
+		{"‘agent-1’ doesn’t have label ‘gpu’", true},
+		{"'agent-1' doesn't have label 'gpu'", true},
+		{"Waiting for next available executor", false},
+		{"Waiting for next available executor on ‘linux’", false},
+		{"Build #5 is already in progress (ETA: 2 min 3 sec)", false},
+	}
+	for _, test := range tests {
+		if got := isUnrunnable(test.why); got != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.why, test.expected, got)
+		}
+	}
+}
+
+func TestConcurrencyBlockSeconds(t *testing.T) {
+	// Job y was left out by the description filter
+	collectedJobs = map[string]bool{"x": true}
+	now := time.Now()
+	queuedAt := func(ago time.Duration) int64 {
+		return now.Add(-ago).UnixNano() / int64(time.Millisecond)
+	}
+	responses := map[string]string{
+		"/queue/api/json": fmt.Sprintf(`{"items": [
+			{"id": 5, "blocked": true, "why": "Build #4 is already in progress (ETA: 1 min 2 sec)", "inQueueSince": %d, "task": {"name": "x"}},
+			{"id": 6, "blocked": true, "why": "Build #4 is already in progress (ETA: 1 min 2 sec)", "inQueueSince": %d, "task": {"name": "x"}},
+			{"id": 9, "blocked": true, "why": "Build #4 is already in progress (ETA: 1 min 2 sec)", "inQueueSince": %d, "task": {"name": "x"}},
+			{"id": 7, "buildable": true, "why": "Waiting for next available executor", "inQueueSince": %d, "task": {"name": "x"}},
+			{"id": 8, "blocked": true, "why": "Build #2 is already in progress (ETA: 1 min 2 sec)", "inQueueSince": %d, "task": {"name": "y"}}
+		]}`, queuedAt(time.Hour), queuedAt(time.Hour), queuedAt(5*time.Second), queuedAt(time.Hour), queuedAt(time.Hour)),
+	}
+	newJenkinsFixture(t, responses)
+	jenkinsQueueItemConcurrencyBlockSeconds.Reset()
+	jenkinsQueueItemUnrunnable.Reset()
+	concurrencyBlockedSince = map[int64]time.Time{5: now.Add(-30 * time.Second)}
+	lastQueueCollection = now.Add(-10 * time.Second)
+
+	collectQueue()
+
+	if got := seriesCount(jenkinsQueueItemConcurrencyBlockSeconds); got != 3 {
+		t.Fatalf("expected 3 concurrency blocked items, got %d", got)
+	}
+	if got := seriesCount(jenkinsQueueItemUnrunnable); got != 4 {
+		t.Errorf("expected queue items of the collected job only, got %d", got)
+	}
+	tests := []struct {
+		id       string
+		expected float64
+	}{
+		// Already seen blocked
+		{"5", 30},
+		// Queued before the previous collection, which did not see it blocked
+		{"6", 10},
+		// Queued and blocked since the previous collection
+		{"9", 5},
+	}
+	for _, test := range tests {
+		if got := metricValue(t, jenkinsQueueItemConcurrencyBlockSeconds.WithLabelValues("x", test.id)); got < test.expected || got > test.expected+5 {
+			t.Errorf("item %s: expected to be blocked for about %vs, got %v", test.id, test.expected, got)
+		}
+	}
+
+	responses["/queue/api/json"] = `{"items": []}`
+	collectQueue()
+	if len(concurrencyBlockedSince) != 0 {
+		t.Errorf("expected items that left the queue to be forgotten, got %v", concurrencyBlockedSince)
+	}
+}
+
+func TestConcurrencyBlockSecondsFirstCollection(t *testing.T) {
+	collectedJobs = map[string]bool{"x": true}
+	queuedAt := time.Now().Add(-time.Minute).UnixNano() / int64(time.Millisecond)
+	newJenkinsFixture(t, map[string]string{
+		"/queue/api/json": fmt.Sprintf(`{"items": [
+			{"id": 5, "blocked": true, "why": "Build #4 is already in progress (ETA: 1 min 2 sec)", "inQueueSince": %d, "task": {"name": "x"}}
+		]}`, queuedAt),
+	})
+	jenkinsQueueItemConcurrencyBlockSeconds.Reset()
+	concurrencyBlockedSince = make(map[int64]time.Time)
+	lastQueueCollection = time.Time{}
+
+	collectQueue()
+
+	// Without a previous collection the whole time in the queue is counted
+	if got := metricValue(t, jenkinsQueueItemConcurrencyBlockSeconds.WithLabelValues("x", "5")); got < 60 || got > 65 {
+		t.Errorf("expected the item to be blocked since it was queued, got %v", got)
+	}
+}
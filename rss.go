@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Atom feed served by Jenkins on /job/<name>/rssAll
+type buildFeed struct {
+	Entries []struct {
+		Title     string `xml:"title"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+// A build extracted from a feed entry
+type feedBuild struct {
+	Number    int
+	Success   bool
+	Timestamp time.Time
+}
+
+// Entry titles look like "job1 #12 (broken since build #10)"
+var feedTitle = regexp.MustCompile(`#(\d+) \((.*)\)$`)
+
+// Parse the builds listed in a job's Atom feed, skipping builds still in progress
+func parseBuildFeed(data []byte) ([]feedBuild, error) {
+	var feed buildFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+	var builds []feedBuild
+	for _, entry := range feed.Entries {
+		match := feedTitle.FindStringSubmatch(strings.TrimSpace(entry.Title))
+		if match == nil || match[2] == "?" {
+			continue
+		}
+		number, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339, entry.Published)
+		if err != nil {
+			continue
+		}
+		builds = append(builds, feedBuild{
+			Number:    number,
+			Success:   !strings.Contains(match[2], "broken"),
+			Timestamp: timestamp,
+		})
+	}
+	return builds, nil
+}
+
+// Collect basic build metrics from the job's RSS feed, used when the JSON API is not available.
+// Returns false when the feed could not be collected.
+func collectJobFromFeed(jobname string) bool {
+	var data string
+	resp, err := jenkinsCli.Requester.Get("/job/"+jobname+"/rssAll", &data, nil)
+	if err != nil {
+		log.Errorf("Unable to get RSS feed for job %s: %s", jobname, err)
+		return false
+	}
+	if resp.StatusCode != 200 {
+		log.Errorf("Unable to get RSS feed for job %s: status %d", jobname, resp.StatusCode)
+		return false
+	}
+	builds, err := parseBuildFeed([]byte(data))
+	if err != nil {
+		log.Errorf("Unable to parse RSS feed for job %s: %s", jobname, err)
+		return false
+	}
+
+	for _, build := range builds {
+		commonArgs := []string{jobname, strconv.Itoa(build.Number)}
+		jenkinsCompletedBuildSuccess.WithLabelValues(commonArgs...).Set(
+			func(success bool) float64 {
+				if success {
+					return 1
+				}
+				return 0
+			}(build.Success))
+		jenkinsCompletedBuildTimestamp.WithLabelValues(commonArgs...).Set(float64(build.Timestamp.Unix()))
+	}
+	log.Debugf("Finished collecting metrics from RSS feed for job: %s", jobname)
+	return true
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestParseBuildFeed(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/rssAll.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builds, err := parseBuildFeed(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The in-progress build #14 is skipped
+	expected := []feedBuild{
+		{Number: 13, Success: true, Timestamp: time.Date(2020, 6, 3, 10, 0, 0, 0, time.UTC)},
+		{Number: 12, Success: false, Timestamp: time.Date(2020, 6, 2, 10, 0, 0, 0, time.UTC)},
+		{Number: 10, Success: true, Timestamp: time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	if len(builds) != len(expected) {
+		t.Fatalf("expected %d builds, got %+v", len(expected), builds)
+	}
+	for i, build := range builds {
+		if build.Number != expected[i].Number || build.Success != expected[i].Success || !build.Timestamp.Equal(expected[i].Timestamp) {
+			t.Errorf("build %d: expected %+v, got %+v", i, expected[i], build)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/bndr/gojenkins"
+	log "github.com/sirupsen/logrus"
+)
+
+// Test report reduced to the stage attribution recorded by the JUnit plugin
+type stageTestReport struct {
+	Suites []struct {
+		// Enclosing stage and parallel branch names, innermost first
+		EnclosingBlockNames []string `json:"enclosingBlockNames"`
+		Cases               []struct {
+			Status string `json:"status"`
+		} `json:"cases"`
+	} `json:"suites"`
+}
+
+// Count failed tests per top level pipeline stage. Suites recorded outside
+// of a stage are left out.
+func stageTestFailures(report stageTestReport) map[string]int {
+	failures := make(map[string]int)
+	for _, suite := range report.Suites {
+		if len(suite.EnclosingBlockNames) == 0 {
+			continue
+		}
+		stage := suite.EnclosingBlockNames[len(suite.EnclosingBlockNames)-1]
+		// Stages without failures are reported with 0
+		if _, ok := failures[stage]; !ok {
+			failures[stage] = 0
+		}
+		for _, testcase := range suite.Cases {
+			if testcase.Status == "FAILED" || testcase.Status == "REGRESSION" {
+				failures[stage]++
+			}
+		}
+	}
+	return failures
+}
+
+// Collect failed test counts per pipeline stage of a build
+func collectStageTests(commonArgs []string, build *gojenkins.Build) {
+	var report stageTestReport
+	_, err := jenkinsCli.Requester.GetJSON(build.Base+"/testReport", &report, map[string]string{
+		"tree": "suites[enclosingBlockNames,cases[status]]",
+	})
+	if err != nil {
+		log.Errorf("Unable to get stage test results for build %s: %s", build.Base, err)
+		return
+	}
+	for stage, failed := range stageTestFailures(report) {
+		jenkinsCompletedBuildStageTestFailCount.WithLabelValues(append(commonArgs, stage)...).Set(float64(failed))
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStageTestFailures(t *testing.T) {
+	var report stageTestReport
+	err := json.Unmarshal([]byte(`{"suites": [
+		{"enclosingBlockNames": ["Build"], "cases": [{"status": "PASSED"}, {"status": "PASSED"}]},
+		{"enclosingBlockNames": ["linux", "Test"], "cases": [{"status": "FAILED"}, {"status": "PASSED"}]},
+		{"enclosingBlockNames": ["windows", "Test"], "cases": [{"status": "REGRESSION"}, {"status": "FIXED"}]},
+		{"enclosingBlockNames": ["Unit", "Checks", "Verify"], "cases": [{"status": "FAILED"}]},
+		{"enclosingBlockNames": [], "cases": [{"status": "FAILED"}]},
+		{"cases": [{"status": "FAILED"}]}
+	]}`), &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failures := stageTestFailures(report)
+
+	// Parallel branches and nested stages are attributed to the top level stage,
+	// suites without stage attribution are left out
+	expected := map[string]int{"Build": 0, "Test": 2, "Verify": 1}
+	if len(failures) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, failures)
+	}
+	for stage, count := range expected {
+		if failures[stage] != count {
+			t.Errorf("%s: expected %d failures, got %d", stage, count, failures[stage])
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Update center status, only the connection check jobs are of interest
+type updateCenter struct {
+	Jobs []struct {
+		Class            string            `json:"_class"`
+		ConnectionStates map[string]string `json:"connectionStates"`
+	} `json:"jobs"`
+}
+
+// Reachability of the update site from the latest connection check.
+// The second value is false when no check has a conclusive result.
+func updateSiteReachable(center updateCenter) (bool, bool) {
+	for i := len(center.Jobs) - 1; i >= 0; i-- {
+		job := center.Jobs[i]
+		if job.Class != "hudson.model.UpdateCenter$ConnectionCheckJob" {
+			continue
+		}
+		switch job.ConnectionStates["updatesite"] {
+		case "OK":
+			return true, true
+		case "FAILED":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// Collect the update center connectivity status
+func collectUpdateCenter() {
+	var center updateCenter
+	resp, err := jenkinsCli.Requester.GetJSON("/updateCenter", &center, map[string]string{"depth": "1"})
+	if err != nil {
+		log.Errorf("Unable to get update center status: %s", err)
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("Unable to get update center status: status %d", resp.StatusCode)
+		return
+	}
+	reachable, ok := updateSiteReachable(center)
+	if !ok {
+		log.Debugf("No conclusive update center connection check")
+		return
+	}
+	jenkinsUpdateCenterReachable.WithLabelValues().Set(func(reachable bool) float64 {
+		if reachable {
+			return 1
+		}
+		return 0
+	}(reachable))
+	cacheAge.markRefreshed("updatecenter")
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestCollectUpdateCenter(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		series    int
+		expected  float64
+	}{
+		{"reachable", map[string]string{"/updateCenter/api/json": `{"jobs": [
+			{"_class": "hudson.model.UpdateCenter$ConnectionCheckJob", "connectionStates": {"internet": "OK", "updatesite": "OK"}}
+		]}`}, 1, 1},
+		{"unreachable", map[string]string{"/updateCenter/api/json": `{"jobs": [
+			{"_class": "hudson.model.UpdateCenter$ConnectionCheckJob", "connectionStates": {"internet": "OK", "updatesite": "OK"}},
+			{"_class": "hudson.model.UpdateCenter$ConnectionCheckJob", "connectionStates": {"internet": "FAILED", "updatesite": "FAILED"}}
+		]}`}, 1, 0},
+		{"inconclusive", map[string]string{"/updateCenter/api/json": `{"jobs": [
+			{"_class": "hudson.model.UpdateCenter$ConnectionCheckJob", "connectionStates": {"internet": "CHECKING", "updatesite": "CHECKING"}}
+		]}`}, 0, 0},
+		{"not found", map[string]string{}, 0, 0},
+	}
+	for _, test := range tests {
+		newJenkinsFixture(t, test.responses)
+		jenkinsUpdateCenterReachable.Reset()
+
+		collectUpdateCenter()
+
+		if got := seriesCount(jenkinsUpdateCenterReachable); got != test.series {
+			t.Errorf("%s: expected %d series, got %d", test.name, test.series, got)
+			continue
+		}
+		if test.series == 1 {
+			if got := metricValue(t, jenkinsUpdateCenterReachable.WithLabelValues()); got != test.expected {
+				t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+			}
+		}
+	}
+}
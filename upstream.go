@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+	log "github.com/sirupsen/logrus"
+)
+
+// An upstream build referenced by a cause
+type upstreamBuild struct {
+	Project string
+	Number  int64
+}
+
+// Extract the upstream builds referenced by the causes of a build
+func upstreamCauses(build *gojenkins.Build) []upstreamBuild {
+	var upstream []upstreamBuild
+	for _, action := range build.GetActions() {
+		for _, cause := range action.Causes {
+			project, _ := cause["upstreamProject"].(string)
+			number, _ := cause["upstreamBuild"].(float64)
+			if project != "" && number > 0 {
+				upstream = append(upstream, upstreamBuild{Project: project, Number: int64(number)})
+			}
+		}
+	}
+	return upstream
+}
+
+// Fetch an upstream build, the project is a full name that can include folders
+func getUpstreamBuild(upstream upstreamBuild) (*gojenkins.Build, error) {
+	build := &gojenkins.Build{
+		Jenkins: jenkinsCli,
+		Raw:     new(gojenkins.BuildResponse),
+		Depth:   1,
+		Base:    "/job/" + strings.Replace(upstream.Project, "/", "/job/", -1) + "/" + strconv.FormatInt(upstream.Number, 10),
+	}
+	if _, err := build.Poll(); err != nil {
+		return nil, err
+	}
+	return build, nil
+}
+
+// Count the transitive upstream builds of a build by following cause chains,
+// going no further than maxDepth levels up
+func upstreamDepth(build *gojenkins.Build, maxDepth int) int {
+	seen := make(map[upstreamBuild]bool)
+	level := []*gojenkins.Build{build}
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		var next []*gojenkins.Build
+		for _, b := range level {
+			for _, upstream := range upstreamCauses(b) {
+				if seen[upstream] {
+					continue
+				}
+				seen[upstream] = true
+				// The causes of the last level are not followed
+				if depth+1 == maxDepth {
+					continue
+				}
+				parent, err := getUpstreamBuild(upstream)
+				if err != nil {
+					log.Debugf("Unable to get upstream build %s #%d: %s", upstream.Project, upstream.Number, err)
+					continue
+				}
+				next = append(next, parent)
+			}
+		}
+		level = next
+	}
+	return len(seen)
+}
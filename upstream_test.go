@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestUpstreamDepth(t *testing.T) {
+	newJenkinsFixture(t, map[string]string{
+		"/job/x/3/api/json": `{"number": 3, "actions": [{"causes": [
+			{"_class": "hudson.model.Cause$UpstreamCause", "upstreamProject": "folder/b", "upstreamBuild": 2}
+		]}]}`,
+		"/job/folder/job/b/2/api/json": `{"number": 2, "actions": [{"causes": [
+			{"_class": "hudson.model.Cause$UpstreamCause", "upstreamProject": "a", "upstreamBuild": 1}
+		]}]}`,
+		"/job/a/1/api/json": `{"number": 1, "actions": [{"causes": [
+			{"_class": "hudson.model.Cause$UserIdCause", "userId": "admin"}
+		]}]}`,
+	})
+	_, build := fixtureBuild("x", 3)
+	if _, err := build.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := upstreamDepth(build, 5); got != 2 {
+		t.Errorf("expected 2 upstream builds, got %d", got)
+	}
+	if got := upstreamDepth(build, 1); got != 1 {
+		t.Errorf("expected maxDepth 1 to stop after 1 upstream build, got %d", got)
+	}
+}